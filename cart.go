@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/metric"
+)
+
+// cartCollector tracks the number of items in the cart and exposes it as an
+// observable gauge. It's the first example of an app-specific collectors.Collector.
+type cartCollector struct {
+	items int64
+}
+
+func (c *cartCollector) Register(meter metric.Meter) error {
+	_, err := meter.Int64ObservableGauge(
+		"cart.items",
+		metric.WithDescription("Current number of items in cart"),
+		metric.WithInt64Callback(func(ctx context.Context, o metric.Int64Observer) error {
+			o.Observe(atomic.LoadInt64(&c.items))
+			return nil
+		}),
+	)
+	return err
+}
+
+func (c *cartCollector) Add(n int64) int64 {
+	return atomic.AddInt64(&c.items, n)
+}
+
+func (c *cartCollector) Items() int64 {
+	return atomic.LoadInt64(&c.items)
+}
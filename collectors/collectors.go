@@ -0,0 +1,42 @@
+// Package collectors provides a pluggable way to register OpenTelemetry
+// instruments against a shared meter, alongside the standard runtime and
+// host metrics every service wants (GC pauses, goroutine counts, heap
+// allocations, CPU, memory, network bytes).
+package collectors
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/contrib/instrumentation/host"
+	"go.opentelemetry.io/contrib/instrumentation/runtime"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Collector registers its own instruments against meter. Implementations
+// plug in application-specific gauges/counters (e.g. a DB pool's open
+// connection count) alongside the built-in runtime and host collectors.
+type Collector interface {
+	Register(meter metric.Meter) error
+}
+
+// Start wires up the standard runtime and host-metrics collectors against
+// mp, then registers every extra Collector against a "collectors" meter
+// taken from the same provider.
+func Start(mp metric.MeterProvider, extra ...Collector) error {
+	if err := runtime.Start(runtime.WithMeterProvider(mp)); err != nil {
+		return fmt.Errorf("start runtime collector: %w", err)
+	}
+
+	if err := host.Start(host.WithMeterProvider(mp)); err != nil {
+		return fmt.Errorf("start host collector: %w", err)
+	}
+
+	meter := mp.Meter("collectors")
+	for _, c := range extra {
+		if err := c.Register(meter); err != nil {
+			return fmt.Errorf("register collector %T: %w", c, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"strings"
+	"time"
+)
+
+// otlpConfig holds the flags needed to point this binary at any OTLP/HTTP or
+// OTLP/gRPC receiver (SigNoz, an OTel collector, VictoriaMetrics' OTLP
+// ingest endpoint, ...) without a recompile.
+type otlpConfig struct {
+	protocol     string
+	endpoint     string
+	insecure     bool
+	headers      map[string]string
+	pushInterval time.Duration
+	path         string
+	jobName      string
+	instance     string
+
+	// globalCardinalityLimit bounds unique attribute sets per instrument,
+	// shared across every instrument on the MeterProvider - see
+	// globalCardinalityLimit in route.go for why this can't be scoped to
+	// http.* alone.
+	globalCardinalityLimit int
+}
+
+func parseOTLPFlags() *otlpConfig {
+	cfg := &otlpConfig{}
+	var headers string
+
+	flag.StringVar(&cfg.protocol, "otlp.protocol", "grpc", "OTLP exporter protocol: grpc|http/protobuf")
+	flag.StringVar(&cfg.endpoint, "otlp.endpoint", "localhost:4317", "OTLP collector endpoint host:port")
+	flag.BoolVar(&cfg.insecure, "otlp.insecure", true, "disable TLS when talking to the OTLP endpoint")
+	flag.StringVar(&headers, "otlp.headers", "", "comma-separated key=value headers sent with every export, e.g. api-key=foo,team=bar")
+	flag.DurationVar(&cfg.pushInterval, "otlp.push-interval", 10*time.Second, "interval between periodic metric exports")
+	flag.StringVar(&cfg.path, "otlp.path", "", "override the OTLP/HTTP ingest path, e.g. /opentelemetry/api/v1/push for VictoriaMetrics")
+	flag.StringVar(&cfg.jobName, "otlp.job-name", "assignment-metrics", "job name attached as the job resource attribute")
+	flag.StringVar(&cfg.instance, "otlp.instance", "", "instance id attached as the instance resource attribute; defaults to hostname when empty")
+	flag.IntVar(&cfg.globalCardinalityLimit, "otel.cardinality-limit", defaultGlobalCardinalityLimit, "max unique attribute sets tracked per instrument, shared across the ENTIRE MeterProvider (all http.*, runtime, host, and cart instruments), before overflowing into otel.metric.overflow=true")
+	flag.Parse()
+
+	cfg.headers = parseHeaders(headers)
+	return cfg
+}
+
+func parseHeaders(raw string) map[string]string {
+	headers := map[string]string{}
+	if raw == "" {
+		return headers
+	}
+	for _, kv := range strings.Split(raw, ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return headers
+}
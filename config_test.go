@@ -0,0 +1,30 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseHeaders(t *testing.T) {
+	cases := []struct {
+		name string
+		raw  string
+		want map[string]string
+	}{
+		{"empty", "", map[string]string{}},
+		{"single", "api-key=foo", map[string]string{"api-key": "foo"}},
+		{"multiple", "api-key=foo,team=bar", map[string]string{"api-key": "foo", "team": "bar"}},
+		{"trims whitespace", " api-key = foo , team = bar ", map[string]string{"api-key": "foo", "team": "bar"}},
+		{"skips entries without =", "api-key=foo,garbage,team=bar", map[string]string{"api-key": "foo", "team": "bar"}},
+		{"value may contain =", "token=a=b=c", map[string]string{"token": "a=b=c"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := parseHeaders(tc.raw)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parseHeaders(%q) = %#v, want %#v", tc.raw, got, tc.want)
+			}
+		})
+	}
+}
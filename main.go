@@ -9,144 +9,246 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
-	"sync/atomic"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/Ankitgl444/signoz-metrics/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
-	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/metric/noop"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 )
 
-func initMeterProvider(ctx context.Context) (func(context.Context) error, error) {
+// retryConfig is the backoff handed to both OTLP exporters (grpc and http use
+// the same shape) so both protocols retry transient failures identically.
+var retryConfig = struct {
+	Enabled         bool
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	MaxElapsedTime  time.Duration
+}{
+	Enabled:         true,
+	InitialInterval: 1 * time.Second,
+	MaxInterval:     30 * time.Second,
+	MaxElapsedTime:  2 * time.Minute,
+}
 
-	// exporter automatically reads OTEL_EXPORTER_OTLP_METRICS_ENDPOINT + HEADERS from env.
-	exporter, err := otlpmetricgrpc.New(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("new otlp metric grpc exporter failed: %w", err)
+// newMetricExporter builds the push exporter selected by cfg.protocol. Both
+// branches share the same endpoint/insecure/headers/retry knobs so operators
+// can repoint the binary at SigNoz, an OTel collector, or VictoriaMetrics'
+// OTLP ingest endpoint with flags alone.
+func newMetricExporter(ctx context.Context, cfg *otlpConfig) (sdkmetric.Exporter, error) {
+	switch cfg.protocol {
+	case "http/protobuf", "http":
+		opts := []otlpmetrichttp.Option{
+			otlpmetrichttp.WithEndpoint(cfg.endpoint),
+			otlpmetrichttp.WithHeaders(cfg.headers),
+			otlpmetrichttp.WithRetry(otlpmetrichttp.RetryConfig(retryConfig)),
+		}
+		if cfg.insecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		}
+		if cfg.path != "" {
+			opts = append(opts, otlpmetrichttp.WithURLPath(cfg.path))
+		}
+		return otlpmetrichttp.New(ctx, opts...)
+	case "grpc":
+		opts := []otlpmetricgrpc.Option{
+			otlpmetricgrpc.WithEndpoint(cfg.endpoint),
+			otlpmetricgrpc.WithHeaders(cfg.headers),
+			otlpmetricgrpc.WithRetry(otlpmetricgrpc.RetryConfig(retryConfig)),
+		}
+		if cfg.insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+		return otlpmetricgrpc.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported otlp.protocol %q (want grpc or http/protobuf)", cfg.protocol)
+	}
+}
+
+// newResource builds the resource shared by the meter and tracer providers,
+// tagging it with the job/instance pair so the same service is identifiable
+// across both the metrics and traces pipelines.
+func newResource(ctx context.Context, cfg *otlpConfig) (*resource.Resource, error) {
+	instance := cfg.instance
+	if instance == "" {
+		if hostname, err := os.Hostname(); err == nil {
+			instance = hostname
+		}
 	}
 
 	// Resource reads OTEL_RESOURCE_ATTRIBUTES (service.name=...) from env.
-	res, err := resource.New(
+	return resource.New(
 		ctx,
 		resource.WithFromEnv(),
 		resource.WithHost(),
 		resource.WithProcess(),
 		resource.WithOS(),
+		resource.WithAttributes(
+			attribute.String("job", cfg.jobName),
+			attribute.String("instance", instance),
+		),
 	)
+}
+
+// httpAllowedAttributes lists the only attributes kept on http.* instruments.
+// Anything else - e.g. a future otelhttp contrib release adding higher-
+// cardinality labels - is dropped at aggregation time rather than exported.
+var httpAllowedAttributes = map[string]bool{
+	"http.method":               true,
+	"http.route":                true,
+	"http.status_code":          true,
+	"http.request.method":       true,
+	"http.response.status_code": true,
+	"network.protocol.version":  true,
+}
+
+// httpAttributeAllowlistView scopes attribute filtering to the http.*
+// instruments via the SDK's real View API, so the allow-list is enforced by
+// the MeterProvider at aggregation time instead of by application code, and
+// doesn't reach into collectors/runtime/host instruments the way a global
+// filter would.
+func httpAttributeAllowlistView() sdkmetric.View {
+	return sdkmetric.NewView(
+		sdkmetric.Instrument{Name: "http.*"},
+		sdkmetric.Stream{
+			AttributeFilter: func(kv attribute.KeyValue) bool {
+				return httpAllowedAttributes[string(kv.Key)]
+			},
+		},
+	)
+}
+
+// initMeterProvider wires up a MeterProvider that both pushes metrics via
+// OTLP and exposes them for scraping. The returned http.Handler should be
+// mounted at a "/metrics" route so that Prometheus, vmagent, or any other
+// pull-based collector can scrape alongside the OTLP push.
+func initMeterProvider(ctx context.Context, cfg *otlpConfig) (func(context.Context) error, http.Handler, error) {
+
+	exporter, err := newMetricExporter(ctx, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("new resource failed: %w", err)
+		return nil, nil, fmt.Errorf("new otlp metric exporter failed: %w", err)
+	}
+
+	// promExporter implements sdkmetric.Reader and registers itself with the
+	// default Prometheus registry so promhttp.Handler() picks it up.
+	promExporter, err := prometheus.New()
+	if err != nil {
+		return nil, nil, fmt.Errorf("new prometheus exporter failed: %w", err)
+	}
+
+	res, err := newResource(ctx, cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("new resource failed: %w", err)
 	}
 
 	mp := sdkmetric.NewMeterProvider(
 		sdkmetric.WithResource(res),
-		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(10*time.Second))),
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(cfg.pushInterval))),
+		sdkmetric.WithReader(promExporter),
+		sdkmetric.WithView(httpAttributeAllowlistView()),
+		globalCardinalityLimit(cfg.globalCardinalityLimit),
 	)
 
 	otel.SetMeterProvider(mp)
-	return mp.Shutdown, nil
+	return mp.Shutdown, promhttp.Handler(), nil
 }
 
-type respWriter struct {
-	http.ResponseWriter
-	status int
-}
+func main() {
+	cfg := parseOTLPFlags()
 
-func (rw *respWriter) WriteHeader(code int) {
-	rw.status = code
-	rw.ResponseWriter.WriteHeader(code)
-}
+	// The SDK reports OTLP partial-success responses through the global
+	// error handler, but the type describing them lives in an internal
+	// package of each exporter and isn't reachable here, so we can only
+	// recognize them by the "partial success" wording in Error().
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		if strings.Contains(err.Error(), "partial success") {
+			log.Printf("otlp metrics exporter: %v", err)
+			return
+		}
+		log.Printf("otel error: %v", err)
+	}))
 
-func main() {
 	ctx := context.Background()
-	shutdown, err := initMeterProvider(ctx)
+	shutdown, metricsHandler, err := initMeterProvider(ctx, cfg)
 	if err != nil {
 		log.Fatalf("init meter provider: %v", err)
 	}
 	defer func() { _ = shutdown(context.Background()) }()
 
-	m := otel.Meter("assignment-metrics")
-
-	// --- Assignment metrics ---
-	// Counter: number of error requests (5xx)
-	errorRequests, _ := m.Int64Counter(
-		"http.error_requests",
-		metric.WithDescription("Count of HTTP 5xx responses"),
-	)
-
-	// Histogram: request latency
-	requestLatencyMs, _ := m.Float64Histogram(
-		"http.duration_ms",
-		metric.WithUnit("ms"),
-		metric.WithDescription("HTTP request latency in milliseconds"),
-	)
-
-	// Gauge: number of items in cart
-	var cartItems int64
-	_, _ = m.Int64ObservableGauge(
-		"cart.items",
-		metric.WithDescription("Current number of items in cart"),
-		metric.WithInt64Callback(func(ctx context.Context, o metric.Int64Observer) error {
-			o.Observe(atomic.LoadInt64(&cartItems))
-			return nil
-		}),
-	)
-
-	withMetrics := func(route string, next http.HandlerFunc) http.HandlerFunc {
-		return func(w http.ResponseWriter, r *http.Request) {
-			start := time.Now()
-			rw := &respWriter{ResponseWriter: w, status: 200}
-
-			next(rw, r)
+	shutdownTracing, err := initTracerProvider(ctx, cfg)
+	if err != nil {
+		log.Fatalf("init tracer provider: %v", err)
+	}
+	defer func() { _ = shutdownTracing(context.Background()) }()
 
-			latMs := float64(time.Since(start).Milliseconds())
+	m := otel.Meter("assignment-metrics")
 
-			attrs := metric.WithAttributes(
-				attribute.String("http.method", r.Method),
-				attribute.String("http.route", route),
-				attribute.Int("http.status_code", rw.status),
-			)
+	cart := &cartCollector{}
+	if err := collectors.Start(otel.GetMeterProvider(), cart); err != nil {
+		log.Fatalf("start collectors: %v", err)
+	}
 
-			requestLatencyMs.Record(r.Context(), latMs, attrs)
+	hm, err := newHTTPMetrics(m)
+	if err != nil {
+		log.Fatalf("init http metrics: %v", err)
+	}
 
-			if rw.status >= 500 {
-				errorRequests.Add(r.Context(), 1, attrs)
+	routes := []Route{
+		{Template: "/ok", Handler: func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(time.Duration(20+rand.Intn(150)) * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte("ok"))
+		}},
+		{Template: "/error", Handler: func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(time.Duration(30+rand.Intn(200)) * time.Millisecond)
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}},
+		{Template: "/cart/add", Handler: func(w http.ResponseWriter, r *http.Request) {
+			n, _ := strconv.Atoi(r.URL.Query().Get("count"))
+			if n <= 0 {
+				n = 1
 			}
-		}
+			total := cart.Add(int64(n))
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(fmt.Sprintf("cartItems=%d", total)))
+		}},
+		{Template: "/cart/items", Handler: func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(fmt.Sprintf("%d", cart.Items())))
+		}},
 	}
 
 	mux := http.NewServeMux()
 
-	mux.HandleFunc("/ok", withMetrics("/ok", func(w http.ResponseWriter, r *http.Request) {
-		time.Sleep(time.Duration(20+rand.Intn(150)) * time.Millisecond)
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("ok"))
-	}))
+	// Pull-based scraping (Prometheus, vmagent) alongside the OTLP push above.
+	mux.Handle("/metrics", metricsHandler)
 
-	mux.HandleFunc("/error", withMetrics("/error", func(w http.ResponseWriter, r *http.Request) {
-		time.Sleep(time.Duration(30+rand.Intn(200)) * time.Millisecond)
-		http.Error(w, "boom", http.StatusInternalServerError)
-	}))
-
-	mux.HandleFunc("/cart/add", withMetrics("/cart/add", func(w http.ResponseWriter, r *http.Request) {
-		n, _ := strconv.Atoi(r.URL.Query().Get("count"))
-		if n <= 0 {
-			n = 1
-		}
-		atomic.AddInt64(&cartItems, int64(n))
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(fmt.Sprintf("cartItems=%d", atomic.LoadInt64(&cartItems))))
-	}))
+	Register(mux, hm, routes)
 
-	mux.HandleFunc("/cart/items", withMetrics("/cart/items", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(fmt.Sprintf("%d", atomic.LoadInt64(&cartItems))))
-	}))
+	// otelhttp wraps every route with spans only - metrics stay the
+	// responsibility of hm via Register above. otelhttp defaults to the
+	// global MeterProvider even when WithMeterProvider is omitted, so it's
+	// pointed at a noop one here; otherwise it would record
+	// http.server.request.duration and friends a second time, on top of the
+	// ones hm already records in semconvStable/semconvDup mode. Recording
+	// hm's metrics against a request context that already carries a span
+	// (set up by this wrapper) is what lets SigNoz attach exemplars linking
+	// a slow-latency bucket back to its trace.
+	handler := otelhttp.NewHandler(mux, "server",
+		otelhttp.WithTracerProvider(otel.GetTracerProvider()),
+		otelhttp.WithMeterProvider(noop.NewMeterProvider()),
+	)
 
-	srv := &http.Server{Addr: ":8080", Handler: mux}
+	srv := &http.Server{Addr: ":8080", Handler: handler}
 
 	// Graceful shutdown
 	go func() {
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// semconvMode reflects OTEL_SEMCONV_STABILITY_OPT_IN, matching the dual-emit
+// behavior otelhttp itself added upstream while the HTTP semantic
+// conventions stabilized.
+type semconvMode int
+
+const (
+	semconvOld    semconvMode = iota // unset: emit only the legacy custom names
+	semconvStable                    // "http": emit only the stable names
+	semconvDup                       // "http/dup": emit both, for dashboard migration
+)
+
+func semconvModeFromEnv() semconvMode {
+	switch os.Getenv("OTEL_SEMCONV_STABILITY_OPT_IN") {
+	case "http":
+		return semconvStable
+	case "http/dup":
+		return semconvDup
+	default:
+		return semconvOld
+	}
+}
+
+func (m semconvMode) emitOld() bool { return m == semconvOld || m == semconvDup }
+func (m semconvMode) emitNew() bool { return m == semconvStable || m == semconvDup }
+
+// requestDurationBuckets matches the boundaries otelhttp uses for
+// http.server.request.duration.
+var requestDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10}
+
+// knownHTTPMethods is used to normalize unregistered/unknown methods to
+// "_OTHER" per the stable http.request.method semantic convention, so a
+// client sending garbage can't create unbounded attribute cardinality.
+var knownHTTPMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true,
+	http.MethodPut: true, http.MethodDelete: true, http.MethodConnect: true,
+	http.MethodOptions: true, http.MethodTrace: true, http.MethodPatch: true,
+}
+
+func normalizeMethod(method string) string {
+	if knownHTTPMethods[method] {
+		return method
+	}
+	return "_OTHER"
+}
+
+func protocolVersion(r *http.Request) string {
+	return strconv.Itoa(r.ProtoMajor) + "." + strconv.Itoa(r.ProtoMinor)
+}
+
+// respWriter captures the status code and response size so httpMetrics can
+// record them after the handler runs.
+type respWriter struct {
+	http.ResponseWriter
+	status       int
+	bytesWritten int64
+}
+
+func (rw *respWriter) WriteHeader(code int) {
+	rw.status = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *respWriter) Write(b []byte) (int, error) {
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytesWritten += int64(n)
+	return n, err
+}
+
+// httpMetrics holds the legacy and stable instruments and records whichever
+// set(s) semconvModeFromEnv() selects. It replaces the original withMetrics
+// closure so the two naming schemes can be swapped, or dual-emitted, without
+// touching call sites.
+type httpMetrics struct {
+	mode semconvMode
+
+	// legacy custom names.
+	errorRequests    metric.Int64Counter
+	requestLatencyMs metric.Float64Histogram
+
+	// stable HTTP server semantic-convention names.
+	requestDuration metric.Float64Histogram
+	requestBodySize metric.Int64Histogram
+	respBodySize    metric.Int64Histogram
+}
+
+func newHTTPMetrics(meter metric.Meter) (*httpMetrics, error) {
+	hm := &httpMetrics{mode: semconvModeFromEnv()}
+	var err error
+
+	hm.errorRequests, err = meter.Int64Counter(
+		"http.error_requests",
+		metric.WithDescription("Count of HTTP 5xx responses"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	hm.requestLatencyMs, err = meter.Float64Histogram(
+		"http.duration_ms",
+		metric.WithUnit("ms"),
+		metric.WithDescription("HTTP request latency in milliseconds"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	hm.requestDuration, err = meter.Float64Histogram(
+		"http.server.request.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of HTTP server requests"),
+		metric.WithExplicitBucketBoundaries(requestDurationBuckets...),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	hm.requestBodySize, err = meter.Int64Histogram(
+		"http.server.request.body.size",
+		metric.WithUnit("By"),
+		metric.WithDescription("Size of HTTP server request bodies"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	hm.respBodySize, err = meter.Int64Histogram(
+		"http.server.response.body.size",
+		metric.WithUnit("By"),
+		metric.WithDescription("Size of HTTP server response bodies"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return hm, nil
+}
+
+// Wrap instruments next, recording the legacy and/or stable metrics
+// depending on hm.mode. Recording against r.Context() - which by the time
+// this runs is already carrying the span otelhttp started - is what lets
+// exemplars link a latency bucket back to its trace. Allow-listing which
+// attributes actually reach the exporter is handled by the AttributeFilter
+// view initMeterProvider installs for the http.* instruments, not here.
+func (hm *httpMetrics) Wrap(route string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := &respWriter{ResponseWriter: w, status: 200}
+
+		next(rw, r)
+
+		elapsed := time.Since(start)
+		ctx := r.Context()
+
+		if hm.mode.emitOld() {
+			oldAttrs := metric.WithAttributes(
+				attribute.String("http.method", r.Method),
+				attribute.String("http.route", route),
+				attribute.Int("http.status_code", rw.status),
+			)
+			hm.requestLatencyMs.Record(ctx, float64(elapsed.Milliseconds()), oldAttrs)
+			if rw.status >= 500 {
+				hm.errorRequests.Add(ctx, 1, oldAttrs)
+			}
+		}
+
+		if hm.mode.emitNew() {
+			newAttrs := metric.WithAttributes(
+				attribute.String("http.request.method", normalizeMethod(r.Method)),
+				attribute.String("http.route", route),
+				attribute.Int("http.response.status_code", rw.status),
+				attribute.String("network.protocol.version", protocolVersion(r)),
+			)
+			hm.requestDuration.Record(ctx, elapsed.Seconds(), newAttrs)
+			// r.ContentLength is -1 when the request is chunked and the size
+			// is unknown upfront; the semconv spec says to omit the
+			// measurement rather than record a negative size.
+			if r.ContentLength >= 0 {
+				hm.requestBodySize.Record(ctx, r.ContentLength, newAttrs)
+			}
+			hm.respBodySize.Record(ctx, rw.bytesWritten, newAttrs)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNormalizeMethod(t *testing.T) {
+	cases := []struct {
+		method string
+		want   string
+	}{
+		{http.MethodGet, http.MethodGet},
+		{http.MethodPost, http.MethodPost},
+		{http.MethodPatch, http.MethodPatch},
+		{"BREW", "_OTHER"},
+		{"", "_OTHER"},
+	}
+
+	for _, tc := range cases {
+		if got := normalizeMethod(tc.method); got != tc.want {
+			t.Errorf("normalizeMethod(%q) = %q, want %q", tc.method, got, tc.want)
+		}
+	}
+}
+
+func TestProtocolVersion(t *testing.T) {
+	cases := []struct {
+		name  string
+		major int
+		minor int
+		want  string
+	}{
+		{"http/1.1", 1, 1, "1.1"},
+		{"http/1.0", 1, 0, "1.0"},
+		{"http/2", 2, 0, "2.0"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.ProtoMajor, r.ProtoMinor = tc.major, tc.minor
+			if got := protocolVersion(r); got != tc.want {
+				t.Errorf("protocolVersion() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSemconvModeFromEnv(t *testing.T) {
+	cases := []struct {
+		env  string
+		want semconvMode
+	}{
+		{"", semconvOld},
+		{"garbage", semconvOld},
+		{"http", semconvStable},
+		{"http/dup", semconvDup},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.env, func(t *testing.T) {
+			t.Setenv("OTEL_SEMCONV_STABILITY_OPT_IN", tc.env)
+			if got := semconvModeFromEnv(); got != tc.want {
+				t.Errorf("semconvModeFromEnv() with env %q = %v, want %v", tc.env, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSemconvModeEmitOldEmitNew(t *testing.T) {
+	cases := []struct {
+		mode    semconvMode
+		wantOld bool
+		wantNew bool
+	}{
+		{semconvOld, true, false},
+		{semconvStable, false, true},
+		{semconvDup, true, true},
+	}
+
+	for _, tc := range cases {
+		if got := tc.mode.emitOld(); got != tc.wantOld {
+			t.Errorf("%v.emitOld() = %v, want %v", tc.mode, got, tc.wantOld)
+		}
+		if got := tc.mode.emitNew(); got != tc.wantNew {
+			t.Errorf("%v.emitNew() = %v, want %v", tc.mode, got, tc.wantNew)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net/http"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// Route pairs a templated path (e.g. "/users/{id}") with its handler. The
+// template, never the raw request path, is what gets recorded as the
+// http.route attribute, so dynamic segments can't blow up metric cardinality.
+type Route struct {
+	Template string
+	Handler  http.HandlerFunc
+}
+
+// Register mounts every route on mux wrapped by hm, using each route's
+// Template - never the raw request path - as the http.route attribute.
+// Allow-listing which attributes actually get recorded is handled by the
+// AttributeFilter view wired into the MeterProvider in initMeterProvider,
+// not here.
+func Register(mux *http.ServeMux, hm *httpMetrics, routes []Route) {
+	for _, route := range routes {
+		mux.HandleFunc(route.Template, hm.Wrap(route.Template, route.Handler))
+	}
+}
+
+const defaultGlobalCardinalityLimit = 2000
+
+// effectiveGlobalCardinalityLimit applies the same "non-positive means use
+// the default" rule sdkmetric.WithCardinalityLimit itself doesn't apply, so
+// an unset/zero flag still gets a sane bound instead of becoming unlimited.
+func effectiveGlobalCardinalityLimit(configured int) int {
+	if configured <= 0 {
+		return defaultGlobalCardinalityLimit
+	}
+	return configured
+}
+
+// globalCardinalityLimit caps the number of unique attribute sets tracked
+// per instrument per collection cycle. The SDK only exposes this as a
+// global, MeterProvider-wide setting (sdkmetric.WithCardinalityLimit) - there
+// is no per-instrument or per-view equivalent in this SDK version - so the
+// same budget is shared by every instrument on the provider, including the
+// chunk0-4 runtime/host collectors and cart.items, not just http.*. Once an
+// instrument hits the limit, the SDK folds any further attribute sets into a
+// single overflow series tagged otel.metric.overflow=true rather than
+// recording them individually.
+func globalCardinalityLimit(configured int) sdkmetric.Option {
+	return sdkmetric.WithCardinalityLimit(effectiveGlobalCardinalityLimit(configured))
+}
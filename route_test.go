@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestEffectiveGlobalCardinalityLimit(t *testing.T) {
+	cases := []struct {
+		configured int
+		want       int
+	}{
+		{0, defaultGlobalCardinalityLimit},
+		{-1, defaultGlobalCardinalityLimit},
+		{500, 500},
+		{defaultGlobalCardinalityLimit, defaultGlobalCardinalityLimit},
+	}
+
+	for _, tc := range cases {
+		if got := effectiveGlobalCardinalityLimit(tc.configured); got != tc.want {
+			t.Errorf("effectiveGlobalCardinalityLimit(%d) = %d, want %d", tc.configured, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// newTraceExporter mirrors newMetricExporter's protocol selection so traces
+// and metrics always ship to the same collector.
+func newTraceExporter(ctx context.Context, cfg *otlpConfig) (*otlptrace.Exporter, error) {
+	switch cfg.protocol {
+	case "http/protobuf", "http":
+		opts := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(cfg.endpoint),
+			otlptracehttp.WithHeaders(cfg.headers),
+		}
+		if cfg.insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+	case "grpc":
+		opts := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(cfg.endpoint),
+			otlptracegrpc.WithHeaders(cfg.headers),
+		}
+		if cfg.insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported otlp.protocol %q (want grpc or http/protobuf)", cfg.protocol)
+	}
+}
+
+// initTracerProvider is the tracing companion to initMeterProvider: it ships
+// spans to the same collector over the same protocol and installs the W3C
+// propagators so trace context flows across service boundaries.
+func initTracerProvider(ctx context.Context, cfg *otlpConfig) (func(context.Context) error, error) {
+	exporter, err := newTraceExporter(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("new otlp trace exporter failed: %w", err)
+	}
+
+	res, err := newResource(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("new resource failed: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithResource(res),
+		sdktrace.WithBatcher(exporter),
+	)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return tp.Shutdown, nil
+}